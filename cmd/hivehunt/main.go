@@ -0,0 +1,89 @@
+// Command hivehunt dumps a Windows registry hive to JSON, optionally
+// replaying its .LOG1/.LOG2 transaction logs first and/or recovering
+// deleted keys and values out of unallocated hbin space.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"harperreynell/SecTools/pkg/hive"
+)
+
+func main() {
+	recoverDeleted := flag.Bool("recover", false, "also carve orphaned nk/vk cells out of unallocated hbin space into a $Deleted branch")
+	log1Path := flag.String("log1", "", "path to the primary's .LOG1 transaction log (both logs are auto-discovered next to the hive if neither -log1 nor -log2 is given)")
+	log2Path := flag.String("log2", "", "path to the primary's .LOG2 transaction log (both logs are auto-discovered next to the hive if neither -log1 nor -log2 is given)")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 2 {
+		fmt.Println("Usage: hivehunt [-recover] [-log1 path] [-log2 path] <Path To Hive> <Output JSON File>")
+		return
+	}
+
+	filePath := args[0]
+
+	primary, err := os.Open(filePath)
+	if err != nil {
+		fmt.Printf("Error opening hive: %v\n", err)
+		return
+	}
+	defer primary.Close()
+
+	if *log1Path == "" && *log2Path == "" {
+		*log1Path, *log2Path = hive.DiscoverLogPaths(filePath)
+	}
+
+	file, summary, err := hive.ReplayLogs(primary, *log1Path, *log2Path)
+	if err != nil {
+		fmt.Printf("Error replaying transaction logs: %v\n", err)
+		return
+	}
+	if file != primary {
+		defer os.Remove(file.Name())
+		defer file.Close()
+	}
+	if summary != nil {
+		fmt.Printf("Replayed %d dirty page(s) from %s\n", summary.PagesApplied, strings.Join(summary.LogFiles, ", "))
+	}
+
+	h, err := hive.Parse(file)
+	if err != nil {
+		fmt.Printf("Error parsing hive: %v\n", err)
+		return
+	}
+
+	out, err := os.Create(args[1])
+	if err != nil {
+		fmt.Printf("Error creating output file: %v\n", err)
+		return
+	}
+	defer out.Close()
+
+	enc := json.NewEncoder(out)
+	keyCount, carvedCount := 0, 0
+	err = h.Walk(&hive.WalkOptions{Recover: *recoverDeleted}, func(k *hive.Key) error {
+		switch {
+		case k.Path == "$Deleted":
+			// synthetic root marking the start of the carved branch
+		case k.Deleted:
+			carvedCount++
+		default:
+			keyCount++
+		}
+		return enc.Encode(k)
+	})
+	if err != nil {
+		fmt.Printf("Error walking hive: %v\n", err)
+		return
+	}
+
+	if *recoverDeleted {
+		fmt.Printf("Recovered %d orphaned cell(s) into $Deleted\n", carvedCount)
+	}
+	fmt.Printf("Successfully converted %s to %s (%d keys)\n", args[0], args[1], keyCount)
+}
@@ -0,0 +1,142 @@
+// Command lnkparse reports the contents of a Windows .lnk shortcut file,
+// either as a human-readable summary or as JSON.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"harperreynell/SecTools/pkg/lnk"
+)
+
+func field(name string, value any) {
+	fmt.Printf("\t%-28s: %v\n", name, value)
+}
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "emit machine-readable JSON instead of the human-readable report")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 1 {
+		fmt.Println("Usage: lnkparse [-json] <file.lnk>")
+		return
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer f.Close()
+
+	sc, err := lnk.ParseLNK(f)
+	if err != nil {
+		fmt.Println("Parse error:", err)
+		return
+	}
+
+	if *jsonOutput {
+		jsonBytes, err := json.MarshalIndent(sc, "", "  ")
+		if err != nil {
+			fmt.Println("Error encoding JSON:", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	fmt.Println("Link information:")
+	field("CreationTime", sc.Header.CreationTime)
+	field("AccessTime", sc.Header.AccessTime)
+	field("WriteTime", sc.Header.WriteTime)
+	field("FileSize", sc.Header.FileSize)
+	field("IconIndex", sc.Header.IconIndex)
+	field("ShowCommand", sc.Header.ShowCommand)
+	field("HotKey", sc.Header.HotKey)
+	field("FileAttributes", sc.Header.FileAttributes)
+
+	if sc.Target != nil {
+		fmt.Println("\nTarget Path:")
+		field("LocalBasePath", sc.Target.LocalBasePath)
+		field("CommonPathSuffix", sc.Target.CommonPathSuffix)
+	}
+
+	if sc.Strings != (lnk.StringData{}) {
+		fmt.Println("\nStringData:")
+		if sc.Strings.Name != "" {
+			field("Name", sc.Strings.Name)
+		}
+		if sc.Strings.RelativePath != "" {
+			field("RelativePath", sc.Strings.RelativePath)
+		}
+		if sc.Strings.WorkingDirectory != "" {
+			field("WorkingDirectory", sc.Strings.WorkingDirectory)
+		}
+		if sc.Strings.Arguments != "" {
+			field("Arguments", sc.Strings.Arguments)
+		}
+		if sc.Strings.IconLocation != "" {
+			field("IconLocation", sc.Strings.IconLocation)
+		}
+	}
+
+	printExtraData(sc.ExtraData)
+}
+
+// printExtraData renders whatever ExtraData blocks were recognized in
+// the same "\tField : value" style as the rest of the report.
+func printExtraData(e *lnk.ExtraData) {
+	if e == nil {
+		return
+	}
+
+	if e.Tracker != nil {
+		fmt.Println("\nTrackerDataBlock:")
+		field("MachineID", e.Tracker.MachineID)
+		field("Droid", e.Tracker.Droid)
+		field("DroidBirth", e.Tracker.DroidBirth)
+		if e.Tracker.MacAddress != "" {
+			field("MacAddress", e.Tracker.MacAddress)
+		}
+	}
+
+	if e.PropertyStore != nil {
+		for _, section := range e.PropertyStore.Sections {
+			fmt.Println("\nPropertyStoreDataBlock:")
+			field("FormatID", section.FormatID)
+			for _, v := range section.Values {
+				field(fmt.Sprintf("  Property 0x%X", v.ID), v.Value)
+			}
+		}
+	}
+
+	if e.EnvironmentVariable != nil {
+		fmt.Println("\nEnvironmentVariableDataBlock:")
+		field("TargetAnsi", e.EnvironmentVariable.TargetAnsi)
+		field("TargetUnicode", e.EnvironmentVariable.TargetUnicode)
+	}
+
+	if e.IconEnvironment != nil {
+		fmt.Println("\nIconEnvironmentDataBlock:")
+		field("TargetAnsi", e.IconEnvironment.TargetAnsi)
+		field("TargetUnicode", e.IconEnvironment.TargetUnicode)
+	}
+
+	if e.Console != nil {
+		fmt.Println("\nConsoleDataBlock:")
+		field("FaceName", e.Console.FaceName)
+		field("FontFamily", e.Console.FontFamily)
+		field("FontSize", e.Console.FontSize)
+		field("ScreenBufferSize", e.Console.ScreenBufferSize)
+		field("WindowSize", e.Console.WindowSize)
+	}
+
+	if e.KnownFolder != nil {
+		fmt.Println("\nKnownFolderDataBlock:")
+		field("KnownFolderID", e.KnownFolder.KnownFolderID)
+		field("Offset", e.KnownFolder.Offset)
+	}
+}
@@ -0,0 +1,157 @@
+package hive
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+	"www.velocidex.com/golang/regparser"
+)
+
+// Registry value type codes (winnt.h REG_*).
+const (
+	regSZ             = 1
+	regExpandSZ       = 2
+	regBinary         = 3
+	regDWORD          = 4
+	regDWORDBigEndian = 5
+	regMultiSZ        = 7
+	regQWORD          = 11
+)
+
+var utf16LE = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+
+func cleanString(s string) string {
+	return strings.TrimRight(s, "\x00")
+}
+
+// convertValue decodes val's data into a JSON-friendly shape, dispatched
+// on its registry type rather than guessed from its contents, dropping
+// the decoded and raw payload (but keeping the name and type) when it
+// exceeds opts.MaxValueSize.
+func convertValue(val *regparser.CM_KEY_VALUE, opts *WalkOptions) Value {
+	v := Value{
+		Name: cleanString(val.ValueName()),
+		Type: val.Type(),
+	}
+
+	if opts.MaxValueSize > 0 && val.DataSize() > opts.MaxValueSize {
+		return v
+	}
+
+	raw := val.ValueData().Data
+	v.RawBase64 = base64.StdEncoding.EncodeToString(raw)
+	v.Data = decodeValueData(val.Type(), raw)
+
+	if val.Type() == regExpandSZ && opts.EnvMap != nil {
+		if s, ok := v.Data.(string); ok {
+			v.Expanded = expandEnv(s, opts.EnvMap)
+		}
+	}
+
+	return v
+}
+
+// decodeValueData decodes raw according to its registry type. Unknown
+// types fall back to base64, same as true REG_BINARY, so callers never
+// silently lose data.
+func decodeValueData(valueType uint32, raw []byte) interface{} {
+	switch valueType {
+	case regSZ, regExpandSZ:
+		return decodeUTF16LE(raw)
+
+	case regMultiSZ:
+		return decodeMultiSZ(raw)
+
+	case regDWORD:
+		if len(raw) != 4 {
+			return base64.StdEncoding.EncodeToString(raw)
+		}
+		return uint64(binary.LittleEndian.Uint32(raw))
+
+	case regDWORDBigEndian:
+		if len(raw) != 4 {
+			return base64.StdEncoding.EncodeToString(raw)
+		}
+		return uint64(binary.BigEndian.Uint32(raw))
+
+	case regQWORD:
+		if len(raw) != 8 {
+			return base64.StdEncoding.EncodeToString(raw)
+		}
+		return binary.LittleEndian.Uint64(raw)
+
+	case regBinary:
+		return base64.StdEncoding.EncodeToString(raw)
+
+	default:
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+}
+
+// decodeUTF16LE decodes a null-terminated UTF-16LE byte buffer, as used
+// by REG_SZ and REG_EXPAND_SZ.
+func decodeUTF16LE(raw []byte) string {
+	decoded, err := utf16LE.NewDecoder().Bytes(raw)
+	if err != nil {
+		return cleanString(string(raw))
+	}
+	return cleanString(string(decoded))
+}
+
+// expandEnv resolves Windows-style %VAR% references against env, leaving
+// unknown or unterminated references untouched.
+func expandEnv(s string, env map[string]string) string {
+	var out strings.Builder
+
+	for {
+		start := strings.IndexByte(s, '%')
+		if start < 0 {
+			out.WriteString(s)
+			break
+		}
+
+		end := strings.IndexByte(s[start+1:], '%')
+		if end < 0 {
+			out.WriteString(s)
+			break
+		}
+		end += start + 1
+
+		out.WriteString(s[:start])
+		name := s[start+1 : end]
+		if val, ok := env[name]; ok {
+			out.WriteString(val)
+		} else {
+			out.WriteString(s[start : end+1])
+		}
+		s = s[end+1:]
+	}
+
+	return out.String()
+}
+
+// decodeMultiSZ splits a REG_MULTI_SZ's UTF-16LE strings on their NUL
+// separators.
+func decodeMultiSZ(raw []byte) []string {
+	if len(raw)%2 != 0 {
+		raw = raw[:len(raw)-1]
+	}
+
+	var out []string
+	start := 0
+	for i := 0; i+1 < len(raw); i += 2 {
+		if raw[i] == 0 && raw[i+1] == 0 {
+			if i > start {
+				out = append(out, decodeUTF16LE(raw[start:i]))
+			}
+			start = i + 2
+		}
+	}
+	if start < len(raw) {
+		out = append(out, decodeUTF16LE(raw[start:]))
+	}
+
+	return out
+}
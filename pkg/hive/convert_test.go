@@ -0,0 +1,86 @@
+package hive
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func utf16LEBytes(s string) []byte {
+	b, err := utf16LE.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return append(b, 0, 0) // NUL terminator
+}
+
+func TestDecodeValueDataDispatchesOnType(t *testing.T) {
+	dword := make([]byte, 4)
+	binary.LittleEndian.PutUint32(dword, 0x01020304)
+
+	dwordBE := make([]byte, 4)
+	binary.BigEndian.PutUint32(dwordBE, 0x01020304)
+
+	qword := make([]byte, 8)
+	binary.LittleEndian.PutUint64(qword, 0x0102030405060708)
+
+	binaryData := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+
+	tests := []struct {
+		name      string
+		valueType uint32
+		raw       []byte
+		want      interface{}
+	}{
+		{"REG_SZ", regSZ, utf16LEBytes("hello"), "hello"},
+		{"REG_EXPAND_SZ", regExpandSZ, utf16LEBytes("%TEMP%"), "%TEMP%"},
+		{"REG_DWORD", regDWORD, dword, uint64(0x01020304)},
+		{"REG_DWORD_BIG_ENDIAN", regDWORDBigEndian, dwordBE, uint64(0x01020304)},
+		{"REG_QWORD", regQWORD, qword, uint64(0x0102030405060708)},
+		{"REG_BINARY", regBinary, binaryData, base64.StdEncoding.EncodeToString(binaryData)},
+		{"unknown type falls back to base64", 99, binaryData, base64.StdEncoding.EncodeToString(binaryData)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeValueData(tt.valueType, tt.raw)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeValueData(%d, ...) = %#v, want %#v", tt.valueType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeValueDataMultiSZ(t *testing.T) {
+	var raw []byte
+	raw = append(raw, utf16LEBytes("one")...)
+	raw = append(raw, utf16LEBytes("two")...)
+	raw = append(raw, 0, 0) // final empty string terminates the REG_MULTI_SZ
+
+	got := decodeValueData(regMultiSZ, raw)
+	want := []string{"one", "two"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeValueData(regMultiSZ, ...) = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"TEMP": `C:\Temp`}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`%TEMP%\foo.txt`, `C:\Temp\foo.txt`},
+		{`%UNKNOWN%\foo.txt`, `%UNKNOWN%\foo.txt`},
+		{`no vars here`, `no vars here`},
+		{`%TEMP unterminated`, `%TEMP unterminated`},
+	}
+
+	for _, tt := range tests {
+		if got := expandEnv(tt.in, env); got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
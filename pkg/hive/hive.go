@@ -0,0 +1,218 @@
+// Package hive parses Windows registry hive (regf) files. It wraps
+// www.velocidex.com/golang/regparser with the flat, JSON-friendly key and
+// value types and filtering options the hivehunt CLI needs, so the parsing
+// logic can be reused outside of that one command.
+package hive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"www.velocidex.com/golang/regparser"
+)
+
+// Value is a single registry value (name/type/data triple) under a Key.
+type Value struct {
+	Name      string      `json:"name"`
+	Type      uint32      `json:"type"`
+	Data      interface{} `json:"data"`
+	Expanded  string      `json:"expanded,omitempty"`
+	RawBase64 string      `json:"raw_base64,omitempty"`
+	Offset    int64       `json:"offset,omitempty"`
+	Deleted   bool        `json:"deleted,omitempty"`
+}
+
+// Key is a single registry key. Unlike the original hivehunt tree, Key
+// has no SubKeys map: Path is already fully qualified, so Walk can
+// report each key as it is visited instead of buffering the whole tree.
+type Key struct {
+	Path    string  `json:"path"`
+	Values  []Value `json:"values,omitempty"`
+	Offset  int64   `json:"offset,omitempty"`
+	Deleted bool    `json:"deleted,omitempty"`
+}
+
+// Hive is a parsed registry hive, ready to be walked.
+type Hive struct {
+	registry *regparser.Registry
+	reader   io.ReaderAt
+}
+
+// Parse opens a regf hive from r. r is typically an *os.File, optionally
+// already passed through ReplayLogs to apply its transaction logs.
+func Parse(r io.ReaderAt) (*Hive, error) {
+	registry, err := regparser.NewRegistry(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing hive: %w", err)
+	}
+
+	return &Hive{registry: registry, reader: r}, nil
+}
+
+// WalkOptions narrows down what Walk visits, so callers can pull a
+// single subtree or skip oversized values out of a hive without
+// buffering the parts they don't want.
+type WalkOptions struct {
+	// IncludePrefixes, if non-empty, limits Walk to keys whose Path has
+	// at least one of these prefixes.
+	IncludePrefixes []string
+	// ExcludePrefixes skips keys whose Path has any of these prefixes.
+	ExcludePrefixes []string
+	// MaxDepth limits how many levels below the root Walk descends.
+	// Zero means unlimited.
+	MaxDepth int
+	// MaxValueSize drops value Data larger than this many bytes, leaving
+	// Data nil. Zero means unlimited.
+	MaxValueSize int64
+	// Recover also carves deleted/orphaned nk and vk cells out of
+	// unallocated hbin space and visits them under a synthetic
+	// "$Deleted" branch, alongside the live tree.
+	Recover bool
+	// EnvMap, if set, is used to resolve REG_EXPAND_SZ values' %VAR%
+	// references into Value.Expanded.
+	EnvMap map[string]string
+}
+
+// Walk visits the root key and every descendant, in depth-first order,
+// calling visit once per key. It stops and returns the first error visit
+// returns. If opts is nil, the whole tree is visited with no filtering.
+func (h *Hive) Walk(opts *WalkOptions, visit func(*Key) error) error {
+	if opts == nil {
+		opts = &WalkOptions{}
+	}
+
+	root := h.registry.OpenKey("")
+	if root == nil {
+		return errors.New("could not locate root key")
+	}
+
+	if err := walkKey(root, "HKCU", 0, opts, visit); err != nil {
+		return err
+	}
+
+	if !opts.Recover {
+		return nil
+	}
+
+	size, err := readerSize(h.reader)
+	if err != nil {
+		return fmt.Errorf("determining hive size for recovery: %w", err)
+	}
+
+	return carveDeleted(h.reader, size, opts, visit)
+}
+
+func walkKey(key *regparser.CM_KEY_NODE, path string, depth int, opts *WalkOptions, visit func(*Key) error) error {
+	if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+		return nil
+	}
+	if excluded(path, opts) {
+		return nil
+	}
+
+	if matchesInclude(path, opts) {
+		k := &Key{Path: path}
+		for _, val := range key.Values() {
+			k.Values = append(k.Values, convertValue(val, opts))
+		}
+		if err := visit(k); err != nil {
+			return err
+		}
+	}
+
+	if !canDescend(path, opts) {
+		return nil
+	}
+
+	for _, sub := range key.Subkeys() {
+		subPath := path + "\\" + cleanString(sub.Name())
+		if err := walkKey(sub, subPath, depth+1, opts, visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// excluded reports whether path falls under one of opts'
+// ExcludePrefixes, in which case the whole subtree is pruned.
+func excluded(path string, opts *WalkOptions) bool {
+	for _, prefix := range opts.ExcludePrefixes {
+		if hasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesInclude reports whether path itself should be visited: either
+// there are no IncludePrefixes (everything matches), or path falls
+// under one of them.
+func matchesInclude(path string, opts *WalkOptions) bool {
+	if len(opts.IncludePrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range opts.IncludePrefixes {
+		if hasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// canDescend reports whether path's subtree might still contain an
+// included key, so recursion should continue even though path itself
+// doesn't match: path may be a (possibly partial) ancestor of an
+// IncludePrefixes entry, not just a descendant of one.
+func canDescend(path string, opts *WalkOptions) bool {
+	if len(opts.IncludePrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range opts.IncludePrefixes {
+		if hasPrefix(path, prefix) || hasPrefix(prefix, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(path, prefix string) bool {
+	return len(path) >= len(prefix) && path[:len(prefix)] == prefix
+}
+
+// EncodeJSON streams every key Walk visits to w as newline-delimited
+// JSON, one object per key, so large hives never need to be buffered
+// into a single in-memory tree.
+func EncodeJSON(w io.Writer, h *Hive, opts *WalkOptions) error {
+	enc := json.NewEncoder(w)
+	return h.Walk(opts, func(k *Key) error {
+		return enc.Encode(k)
+	})
+}
+
+// readerSize determines the size of r's backing data, trying the
+// interfaces that common io.ReaderAt implementations (*os.File,
+// *bytes.Reader) expose, then falling back to io.Seeker.
+func readerSize(r io.ReaderAt) (int64, error) {
+	if sized, ok := r.(interface{ Size() int64 }); ok {
+		return sized.Size(), nil
+	}
+	if f, ok := r.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		size, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		return size, nil
+	}
+	return 0, errors.New("reader does not expose its size")
+}
@@ -0,0 +1,69 @@
+package hive
+
+import "testing"
+
+func TestCanDescendReachesNestedIncludePrefix(t *testing.T) {
+	opts := &WalkOptions{IncludePrefixes: []string{`HKCU\Software\Foo`}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`HKCU`, true},                  // root: ancestor of the include prefix
+		{`HKCU\Software`, true},         // still an ancestor
+		{`HKCU\Software\Foo`, true},     // exact match
+		{`HKCU\Software\Foo\Bar`, true}, // descendant of an included key
+		{`HKCU\Other`, false},           // neither an ancestor nor a descendant
+	}
+
+	for _, tt := range tests {
+		if got := canDescend(tt.path, opts); got != tt.want {
+			t.Errorf("canDescend(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesIncludeOnlyExactSubtree(t *testing.T) {
+	opts := &WalkOptions{IncludePrefixes: []string{`HKCU\Software\Foo`}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`HKCU`, false},
+		{`HKCU\Software`, false},
+		{`HKCU\Software\Foo`, true},
+		{`HKCU\Software\Foo\Bar`, true},
+		{`HKCU\Other`, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesInclude(tt.path, opts); got != tt.want {
+			t.Errorf("matchesInclude(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExcludedPrunesSubtree(t *testing.T) {
+	opts := &WalkOptions{ExcludePrefixes: []string{`HKCU\Software\Bar`}}
+
+	if excluded(`HKCU\Software`, opts) {
+		t.Error("excluded(ancestor) = true, want false")
+	}
+	if !excluded(`HKCU\Software\Bar`, opts) {
+		t.Error("excluded(exact match) = false, want true")
+	}
+	if !excluded(`HKCU\Software\Bar\Baz`, opts) {
+		t.Error("excluded(descendant) = false, want true")
+	}
+}
+
+func TestNoIncludePrefixesMatchesEverything(t *testing.T) {
+	opts := &WalkOptions{}
+	if !matchesInclude(`HKCU\Anything`, opts) {
+		t.Error("matchesInclude with no IncludePrefixes should match everything")
+	}
+	if !canDescend(`HKCU\Anything`, opts) {
+		t.Error("canDescend with no IncludePrefixes should always allow recursion")
+	}
+}
@@ -0,0 +1,143 @@
+package hive
+
+import (
+	"fmt"
+	"os"
+
+	"www.velocidex.com/golang/regparser"
+)
+
+// hvleSignature is the "HvLE" magic that marks a transaction log entry.
+const hvleSignature = 0x454C7648
+
+// ReplaySummary is surfaced in the final output so analysts know how
+// much of the parsed hive was reconstructed from a transaction log
+// rather than read straight off the primary.
+type ReplaySummary struct {
+	LogFiles     []string
+	PagesApplied int
+}
+
+// DiscoverLogPaths looks for <primary>.LOG1 and <primary>.LOG2 next to
+// the primary hive when the caller didn't pass explicit -log1/-log2
+// paths.
+func DiscoverLogPaths(primaryPath string) (string, string) {
+	log1 := primaryPath + ".LOG1"
+	log2 := primaryPath + ".LOG2"
+
+	if _, err := os.Stat(log1); err != nil {
+		log1 = ""
+	}
+	if _, err := os.Stat(log2); err != nil {
+		log2 = ""
+	}
+
+	return log1, log2
+}
+
+// ReplayLogs hands the primary hive and whichever of log1Path/log2Path
+// exist to regparser's own transaction log replay - it picks the newer
+// of the two logs and applies every dirty page it records - and returns
+// a temp file holding the reconstructed hive. If neither log file can be
+// opened it returns primary unchanged and a nil summary, in which case the caller must not close
+// or remove the returned file itself.
+func ReplayLogs(primary *os.File, log1Path, log2Path string) (*os.File, *ReplaySummary, error) {
+	var logFiles []*os.File
+	var logNames []string
+
+	for _, p := range []string{log1Path, log2Path} {
+		if p == "" {
+			continue
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		logFiles = append(logFiles, f)
+		logNames = append(logNames, p)
+	}
+
+	if len(logFiles) == 0 {
+		return primary, nil, nil
+	}
+
+	applied, err := countDirtyPages(primary, logFiles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("inspecting transaction logs: %w", err)
+	}
+
+	recovered, err := regparser.RecoverHive(primary, logFiles...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("replaying transaction logs: %w", err)
+	}
+
+	return recovered, &ReplaySummary{LogFiles: logNames, PagesApplied: applied}, nil
+}
+
+// countDirtyPages walks each log's HvLE entries the same way
+// regparser.RecoverHive does internally, purely so the number of pages
+// it is about to replay can be reported back to the caller. It must
+// skip exactly the logs RecoverHive itself skips - empty, stale, or an
+// unsupported log version - or the two would disagree about what got
+// applied.
+func countDirtyPages(primary *os.File, logFiles []*os.File) (int, error) {
+	baseReg, err := regparser.NewRegistry(primary)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", primary.Name(), err)
+	}
+
+	total := 0
+
+	for _, f := range logFiles {
+		stat, err := f.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", f.Name(), err)
+		}
+		if stat.Size() == 0 {
+			// A clean checkpoint leaves an empty log behind; RecoverHive
+			// treats that as a normal no-op rather than an error.
+			continue
+		}
+
+		reg, err := regparser.NewRegistry(f)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", f.Name(), err)
+		}
+
+		if reg.BaseBlock.Type() == 1 || reg.BaseBlock.Type() == 2 {
+			// Older log format RecoverHive doesn't replay.
+			continue
+		}
+
+		if reg.BaseBlock.Sequence1() < baseReg.BaseBlock.Sequence2() {
+			// Stale log: the primary already reflects everything in it.
+			continue
+		}
+
+		offset := int64(0x200) // log entries always start here, per the log base block layout
+		for {
+			probe := make([]byte, 1)
+			if n, err := f.ReadAt(probe, offset); n != 1 || err != nil {
+				break
+			}
+
+			entry := &regparser.HIVE_LOG_ENTRY{
+				Reader:  reg.Reader,
+				Offset:  offset,
+				Profile: reg.Profile,
+			}
+			if entry.Signature() != hvleSignature {
+				break
+			}
+			if entry.SequenceNumber() == reg.BaseBlock.Sequence2() {
+				break
+			}
+
+			total += len(entry.GetDirtyPages())
+			offset += int64(entry.LogEntrySize())
+		}
+	}
+
+	return total, nil
+}
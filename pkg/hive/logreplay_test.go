@@ -0,0 +1,127 @@
+package hive
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newHiveHeader builds a minimal regf base block - just enough for
+// regparser.NewRegistry to accept it and for the Sequence1/Sequence2/Type
+// checks in countDirtyPages to see the values under test.
+func newHiveHeader(sequence1, sequence2, hiveType uint32) []byte {
+	buf := make([]byte, 512)
+	copy(buf[0:4], "regf")
+	binary.LittleEndian.PutUint32(buf[4:8], sequence1)
+	binary.LittleEndian.PutUint32(buf[8:12], sequence2)
+	binary.LittleEndian.PutUint32(buf[28:32], hiveType)
+	return buf
+}
+
+// newLogFile builds a log file with the given base block followed by one
+// applied HvLE entry (one 8-byte dirty page) and a terminal entry whose
+// sequence number equals logSequence2, exactly as regparser.RecoverHive
+// expects to find the end of the log.
+func newLogFile(logSequence1, logSequence2 uint32) []byte {
+	buf := append([]byte{}, newHiveHeader(logSequence1, logSequence2, 0)...)
+	buf = append(buf, make([]byte, 0x200-len(buf))...)
+
+	entry := make([]byte, 56) // 40-byte header + one 8-byte dirty page ref + 8 bytes of page data
+	copy(entry[0:4], "HvLE")
+	binary.LittleEndian.PutUint32(entry[4:8], uint32(len(entry))) // LogEntrySize
+	binary.LittleEndian.PutUint32(entry[12:16], logSequence2-1)   // SequenceNumber, != logSequence2
+	binary.LittleEndian.PutUint32(entry[20:24], 1)                // DirtyPagesCount
+	binary.LittleEndian.PutUint32(entry[40:44], 0)                // dirty page ref: PageOffset
+	binary.LittleEndian.PutUint32(entry[44:48], 8)                // dirty page ref: PageSize
+	buf = append(buf, entry...)
+
+	terminal := make([]byte, 16)
+	copy(terminal[0:4], "HvLE")
+	binary.LittleEndian.PutUint32(terminal[12:16], logSequence2) // SequenceNumber == logSequence2: stop here
+	buf = append(buf, terminal...)
+
+	return buf
+}
+
+func writeTempFile(t *testing.T, name string, data []byte) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestCountDirtyPagesSkipsEmptyLog(t *testing.T) {
+	primary := writeTempFile(t, "primary", newHiveHeader(1, 100, 0))
+	log1 := writeTempFile(t, "log1", nil)
+
+	got, err := countDirtyPages(primary, []*os.File{log1})
+	if err != nil {
+		t.Fatalf("countDirtyPages: %v", err)
+	}
+	if got != 0 {
+		t.Errorf("countDirtyPages = %d, want 0 for an empty log", got)
+	}
+}
+
+func TestCountDirtyPagesSkipsStaleLog(t *testing.T) {
+	primary := writeTempFile(t, "primary", newHiveHeader(1, 100, 0))
+	// Log starts before the primary's current sequence: already applied.
+	log1 := writeTempFile(t, "log1", newLogFile(50, 51))
+
+	got, err := countDirtyPages(primary, []*os.File{log1})
+	if err != nil {
+		t.Fatalf("countDirtyPages: %v", err)
+	}
+	if got != 0 {
+		t.Errorf("countDirtyPages = %d, want 0 for a stale log", got)
+	}
+}
+
+func TestCountDirtyPagesSkipsUnsupportedVersion(t *testing.T) {
+	primary := writeTempFile(t, "primary", newHiveHeader(1, 100, 0))
+	log1 := writeTempFile(t, "log1", newHiveHeader(100, 101, 1)) // Type 1: unsupported
+
+	got, err := countDirtyPages(primary, []*os.File{log1})
+	if err != nil {
+		t.Fatalf("countDirtyPages: %v", err)
+	}
+	if got != 0 {
+		t.Errorf("countDirtyPages = %d, want 0 for an unsupported log version", got)
+	}
+}
+
+func TestCountDirtyPagesCountsApplicableLog(t *testing.T) {
+	primary := writeTempFile(t, "primary", newHiveHeader(1, 100, 0))
+	log1 := writeTempFile(t, "log1", newLogFile(100, 101))
+
+	got, err := countDirtyPages(primary, []*os.File{log1})
+	if err != nil {
+		t.Fatalf("countDirtyPages: %v", err)
+	}
+	if got != 1 {
+		t.Errorf("countDirtyPages = %d, want 1 dirty page applied", got)
+	}
+}
+
+func TestReplayLogsSkipsEmptyLogWithoutError(t *testing.T) {
+	primary := writeTempFile(t, "primary", newHiveHeader(1, 100, 0))
+	log1 := writeTempFile(t, "primary.LOG1", nil)
+
+	recovered, summary, err := ReplayLogs(primary, log1.Name(), "")
+	if err != nil {
+		t.Fatalf("ReplayLogs: %v", err)
+	}
+	defer recovered.Close()
+
+	if summary.PagesApplied != 0 {
+		t.Errorf("PagesApplied = %d, want 0 for an empty log", summary.PagesApplied)
+	}
+}
@@ -0,0 +1,220 @@
+package hive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Hive layout constants used when carving cells directly out of the raw
+// file, bypassing regparser entirely (it only walks allocated cells
+// reachable from the root key).
+const (
+	baseBlockSize = 0x1000 // regf header occupies the first hbin-sized block
+	hbinSignature = "hbin"
+
+	nkSignature = "nk"
+	vkSignature = "vk"
+
+	// Offsets below are relative to the start of the cell (i.e. include
+	// the leading 4-byte cell size field).
+	nkHeaderSize = 80 // size field + "nk" + fixed nk fields, before the name
+	vkHeaderSize = 24 // size field + "vk" + fixed vk fields, before the name
+
+	// Known bits for CM_KEY_NODE.Flags (KEY_IS_VOLATILE, KEY_HIVE_EXIT,
+	// KEY_HIVE_ENTRY, KEY_NO_DELETE, KEY_SYM_LINK, KEY_COMP_NAME,
+	// KEY_PREDEF_HANDLE and the two "cached" bits). Anything outside this
+	// mask is not a combination the kernel ever writes.
+	nkKnownFlagsMask = 0x10ff
+
+	maxCarvedNameLen = 255
+)
+
+// carveDeleted scans every hbin in the hive for free (unallocated) cells,
+// reinterprets their leading bytes as orphaned nk/vk records, and visits
+// the survivors under a synthetic "$Deleted" key.
+func carveDeleted(r io.ReaderAt, size int64, opts *WalkOptions, visit func(*Key) error) error {
+	if err := visit(&Key{Path: "$Deleted", Deleted: true}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, size)
+	if _, err := r.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return fmt.Errorf("reading hive for recovery: %w", err)
+	}
+
+	for hbinOff := int64(baseBlockSize); hbinOff+32 <= size; {
+		if !bytes.Equal(buf[hbinOff:hbinOff+4], []byte(hbinSignature)) {
+			break
+		}
+
+		hbinLen := int64(binary.LittleEndian.Uint32(buf[hbinOff+8 : hbinOff+12]))
+		if hbinLen <= 0 || hbinOff+hbinLen > size {
+			break
+		}
+
+		if err := carveHbin(buf, hbinOff+32, hbinOff+hbinLen, size, opts, visit); err != nil {
+			return err
+		}
+		hbinOff += hbinLen
+	}
+
+	return nil
+}
+
+// carveHbin walks the cells within a single hbin (from cellsStart to
+// hbinEnd) and attempts to carve every free cell it finds.
+func carveHbin(buf []byte, cellsStart, hbinEnd, hiveSize int64, opts *WalkOptions, visit func(*Key) error) error {
+	for cellOff := cellsStart; cellOff+4 <= hbinEnd; {
+		rawSize := int32(binary.LittleEndian.Uint32(buf[cellOff : cellOff+4]))
+		cellSize := int64(rawSize)
+		if cellSize < 0 {
+			cellSize = -cellSize
+		}
+		if cellSize < 8 || cellOff+cellSize > hbinEnd {
+			break
+		}
+
+		// Only free (unallocated) cells are candidates: a positive size
+		// marks a cell the kernel has released back to the hbin, whose
+		// old nk/vk contents are left behind until something overwrites
+		// them.
+		if rawSize > 0 {
+			if err := carveCell(buf, cellOff, cellSize, hiveSize, opts, visit); err != nil {
+				return err
+			}
+		}
+
+		cellOff += cellSize
+	}
+
+	return nil
+}
+
+// carveCell inspects the signature of a single free cell and dispatches
+// to the matching record carver.
+func carveCell(buf []byte, cellOff, cellSize, hiveSize int64, opts *WalkOptions, visit func(*Key) error) error {
+	if cellSize < 6 {
+		return nil
+	}
+
+	switch string(buf[cellOff+4 : cellOff+6]) {
+	case nkSignature:
+		key := carveNK(buf, cellOff, cellSize, hiveSize)
+		if key == nil {
+			return nil
+		}
+		key.Path = fmt.Sprintf("$Deleted\\$0x%x_%s", cellOff, key.Path)
+		if excluded(key.Path, opts) || !matchesInclude(key.Path, opts) {
+			return nil
+		}
+		return visit(key)
+	case vkSignature:
+		val := carveVK(buf, cellOff, cellSize, opts)
+		if val == nil {
+			return nil
+		}
+		path := fmt.Sprintf("$Deleted\\$0x%x_%s", cellOff, val.Name)
+		if excluded(path, opts) || !matchesInclude(path, opts) {
+			return nil
+		}
+		return visit(&Key{
+			Path:    path,
+			Values:  []Value{*val},
+			Deleted: true,
+		})
+	}
+
+	return nil
+}
+
+// carveNK attempts to reconstruct a Key from a free cell whose first
+// bytes look like a CM_KEY_NODE. Returns nil if the candidate fails any
+// plausibility check.
+func carveNK(buf []byte, cellOff, cellSize, hiveSize int64) *Key {
+	if cellSize < nkHeaderSize {
+		return nil
+	}
+
+	flags := binary.LittleEndian.Uint16(buf[cellOff+6 : cellOff+8])
+	if flags&^uint16(nkKnownFlagsMask) != 0 {
+		return nil
+	}
+
+	parentOffset := binary.LittleEndian.Uint32(buf[cellOff+20 : cellOff+24])
+	if int64(parentOffset) != 0xffffffff && int64(parentOffset) >= hiveSize {
+		return nil
+	}
+
+	valueListOffset := binary.LittleEndian.Uint32(buf[cellOff+44 : cellOff+48])
+	if int64(valueListOffset) != 0xffffffff && int64(valueListOffset) >= hiveSize {
+		return nil
+	}
+
+	nameLength := int64(binary.LittleEndian.Uint16(buf[cellOff+76 : cellOff+78]))
+	if nameLength <= 0 || nameLength > maxCarvedNameLen {
+		return nil
+	}
+	if cellOff+nkHeaderSize+nameLength > cellOff+cellSize {
+		return nil
+	}
+
+	nameStart := cellOff + nkHeaderSize
+	name := cleanString(string(buf[nameStart : nameStart+nameLength]))
+	if name == "" {
+		return nil
+	}
+
+	return &Key{
+		Path:    name,
+		Offset:  cellOff,
+		Deleted: true,
+	}
+}
+
+// carveVK attempts to reconstruct a Value from a free cell whose first
+// bytes look like a CM_KEY_VALUE.
+func carveVK(buf []byte, cellOff, cellSize int64, opts *WalkOptions) *Value {
+	if cellSize < vkHeaderSize {
+		return nil
+	}
+
+	nameLength := int64(binary.LittleEndian.Uint16(buf[cellOff+6 : cellOff+8]))
+	if nameLength < 0 || nameLength > maxCarvedNameLen {
+		return nil
+	}
+	if cellOff+vkHeaderSize+nameLength > cellOff+cellSize {
+		return nil
+	}
+
+	valueType := binary.LittleEndian.Uint32(buf[cellOff+16 : cellOff+20])
+	if valueType > 11 {
+		return nil
+	}
+
+	name := "(default)"
+	if nameLength > 0 {
+		nameStart := cellOff + vkHeaderSize
+		name = cleanString(string(buf[nameStart : nameStart+nameLength]))
+	}
+
+	dataLength := binary.LittleEndian.Uint32(buf[cellOff+8 : cellOff+12])
+	var data interface{}
+	if dataLength&0x80000000 != 0 {
+		// Top bit set: the value's data (<=4 bytes) is stored inline in
+		// the DataOffset field rather than in a separate cell.
+		size := int64(dataLength &^ 0x80000000)
+		if opts.MaxValueSize <= 0 || size <= opts.MaxValueSize {
+			data = binary.LittleEndian.Uint32(buf[cellOff+12 : cellOff+16])
+		}
+	}
+
+	return &Value{
+		Name:    name,
+		Type:    valueType,
+		Data:    data,
+		Offset:  cellOff,
+		Deleted: true,
+	}
+}
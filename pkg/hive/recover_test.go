@@ -0,0 +1,164 @@
+package hive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// newNKCell builds a minimal free (positive-size) nk cell at offset 0 of
+// a cellSize-byte buffer, with a plausible parent/value-list offset and
+// the given name.
+func newNKCell(cellSize int64, name string) []byte {
+	buf := make([]byte, cellSize)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(cellSize)) // positive: free cell
+	copy(buf[4:6], nkSignature)
+	binary.LittleEndian.PutUint16(buf[6:8], 0x0020) // KEY_COMP_NAME, a known bit
+	binary.LittleEndian.PutUint32(buf[20:24], 0xffffffff)
+	binary.LittleEndian.PutUint32(buf[44:48], 0xffffffff)
+	binary.LittleEndian.PutUint16(buf[76:78], uint16(len(name)))
+	copy(buf[nkHeaderSize:], name)
+	return buf
+}
+
+func TestCarveNKValid(t *testing.T) {
+	cell := newNKCell(96, "Orphaned")
+	key := carveNK(cell, 0, 96, 1<<20)
+	if key == nil {
+		t.Fatal("carveNK returned nil for a well-formed cell")
+	}
+	if key.Path != "Orphaned" || !key.Deleted {
+		t.Errorf("carveNK = %+v, want Path=Orphaned Deleted=true", key)
+	}
+}
+
+func TestCarveNKRejectsUnknownFlags(t *testing.T) {
+	cell := newNKCell(96, "Orphaned")
+	binary.LittleEndian.PutUint16(cell[6:8], 0xFFFF) // bits outside nkKnownFlagsMask
+	if key := carveNK(cell, 0, 96, 1<<20); key != nil {
+		t.Errorf("carveNK = %+v, want nil for an implausible flags byte", key)
+	}
+}
+
+func TestCarveNKRejectsOutOfRangeParent(t *testing.T) {
+	cell := newNKCell(96, "Orphaned")
+	binary.LittleEndian.PutUint32(cell[20:24], 0xFFFFFFF0) // not -1, and past hiveSize
+	if key := carveNK(cell, 0, 96, 16); key != nil {
+		t.Errorf("carveNK = %+v, want nil for a parent offset past the hive", key)
+	}
+}
+
+func TestCarveNKRejectsNameLongerThanCell(t *testing.T) {
+	cell := newNKCell(96, "Orphaned")
+	binary.LittleEndian.PutUint16(cell[76:78], 1000) // name won't fit in the cell
+	if key := carveNK(cell, 0, 96, 1<<20); key != nil {
+		t.Errorf("carveNK = %+v, want nil when the declared name length overruns the cell", key)
+	}
+}
+
+func newVKCell(cellSize int64, name string, valueType uint32) []byte {
+	buf := make([]byte, cellSize)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(cellSize))
+	copy(buf[4:6], vkSignature)
+	binary.LittleEndian.PutUint16(buf[6:8], uint16(len(name)))
+	binary.LittleEndian.PutUint32(buf[16:20], valueType)
+	copy(buf[vkHeaderSize:], name)
+	return buf
+}
+
+func TestCarveVKValid(t *testing.T) {
+	cell := newVKCell(40, "OrphanedValue", regSZ)
+	val := carveVK(cell, 0, 40, &WalkOptions{})
+	if val == nil {
+		t.Fatal("carveVK returned nil for a well-formed cell")
+	}
+	if val.Name != "OrphanedValue" || val.Type != regSZ || !val.Deleted {
+		t.Errorf("carveVK = %+v, want Name=OrphanedValue Type=%d Deleted=true", val, regSZ)
+	}
+}
+
+func TestCarveVKRejectsOutOfRangeType(t *testing.T) {
+	cell := newVKCell(40, "OrphanedValue", 99) // REG_* types only go up to 11
+	if val := carveVK(cell, 0, 40, &WalkOptions{}); val != nil {
+		t.Errorf("carveVK = %+v, want nil for an out-of-range value type", val)
+	}
+}
+
+func TestCarveDeletedFindsOrphanedKey(t *testing.T) {
+	const hbinCellsStart = baseBlockSize + 32
+	nk := newNKCell(96, "Orphaned")
+
+	hbinLen := int64(0x1000)
+	size := baseBlockSize + hbinLen
+
+	buf := make([]byte, size)
+	copy(buf[baseBlockSize:baseBlockSize+4], hbinSignature)
+	binary.LittleEndian.PutUint32(buf[baseBlockSize+8:baseBlockSize+12], uint32(hbinLen))
+	copy(buf[hbinCellsStart:hbinCellsStart+int64(len(nk))], nk)
+
+	var visited []*Key
+	err := carveDeleted(bytes.NewReader(buf), size, &WalkOptions{}, func(k *Key) error {
+		visited = append(visited, k)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("carveDeleted: %v", err)
+	}
+
+	if len(visited) != 2 {
+		t.Fatalf("visited %d keys, want 2 ($Deleted root + carved key): %+v", len(visited), visited)
+	}
+	if visited[0].Path != "$Deleted" {
+		t.Errorf("visited[0].Path = %q, want $Deleted", visited[0].Path)
+	}
+	if visited[1].Path != `$Deleted\$0x1020_Orphaned` {
+		t.Errorf("visited[1].Path = %q, want $Deleted\\$0x1020_Orphaned", visited[1].Path)
+	}
+}
+
+func TestCarveDeletedHonorsExcludePrefixes(t *testing.T) {
+	const hbinCellsStart = baseBlockSize + 32
+	nk := newNKCell(96, "Orphaned")
+
+	hbinLen := int64(0x1000)
+	size := baseBlockSize + hbinLen
+
+	buf := make([]byte, size)
+	copy(buf[baseBlockSize:baseBlockSize+4], hbinSignature)
+	binary.LittleEndian.PutUint32(buf[baseBlockSize+8:baseBlockSize+12], uint32(hbinLen))
+	copy(buf[hbinCellsStart:hbinCellsStart+int64(len(nk))], nk)
+
+	opts := &WalkOptions{ExcludePrefixes: []string{`$Deleted\$0x1020`}}
+
+	var visited []*Key
+	err := carveDeleted(bytes.NewReader(buf), size, opts, func(k *Key) error {
+		visited = append(visited, k)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("carveDeleted: %v", err)
+	}
+
+	if len(visited) != 1 || visited[0].Path != "$Deleted" {
+		t.Errorf("visited = %+v, want just the $Deleted root with the carved key excluded", visited)
+	}
+}
+
+func TestCarveVKDropsInlineDataOverMaxValueSize(t *testing.T) {
+	cell := newVKCell(40, "OrphanedValue", regDWORD)
+	binary.LittleEndian.PutUint32(cell[8:12], 0x80000004)  // top bit set: 4-byte inline data
+	binary.LittleEndian.PutUint32(cell[12:16], 0x01020304) // inline data
+
+	val := carveVK(cell, 0, 40, &WalkOptions{})
+	if val == nil || val.Data == nil {
+		t.Fatalf("carveVK = %+v, want inline Data when MaxValueSize is unset", val)
+	}
+
+	val = carveVK(cell, 0, 40, &WalkOptions{MaxValueSize: 2})
+	if val == nil {
+		t.Fatal("carveVK returned nil")
+	}
+	if val.Data != nil {
+		t.Errorf("carveVK.Data = %#v, want nil when inline data exceeds MaxValueSize", val.Data)
+	}
+}
@@ -0,0 +1,377 @@
+package lnk
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// ExtraData block signatures (MS-SHLLINK 2.5).
+const (
+	sigConsoleDataBlock             = 0xA0000002
+	sigEnvironmentVariableDataBlock = 0xA0000001
+	sigIconEnvironmentDataBlock     = 0xA0000007
+	sigKnownFolderDataBlock         = 0xA000000B
+	sigPropertyStoreDataBlock       = 0xA0000009
+	sigTrackerDataBlock             = 0xA0000003
+)
+
+// ExtraData collects every ExtraData block this package knows how to
+// parse. Blocks whose signature isn't recognized are skipped.
+type ExtraData struct {
+	Tracker             *TrackerDataBlock             `json:"tracker,omitempty"`
+	PropertyStore       *PropertyStoreDataBlock       `json:"property_store,omitempty"`
+	EnvironmentVariable *EnvironmentVariableDataBlock `json:"environment_variable,omitempty"`
+	Console             *ConsoleDataBlock             `json:"console,omitempty"`
+	IconEnvironment     *IconEnvironmentDataBlock     `json:"icon_environment,omitempty"`
+	KnownFolder         *KnownFolderDataBlock         `json:"known_folder,omitempty"`
+}
+
+// TrackerDataBlock carries the NetBIOS machine name and MAC address of
+// the host the shortcut was originally created on, via the Link Track
+// Me droid/birth-droid ObjectIDs.
+type TrackerDataBlock struct {
+	Version    uint32    `json:"version"`
+	MachineID  string    `json:"machine_id"`
+	Droid      [2]string `json:"droid"`       // VolumeID, ObjectID
+	DroidBirth [2]string `json:"droid_birth"` // VolumeID, ObjectID
+	MacAddress string    `json:"mac_address,omitempty"`
+}
+
+// PropertyStoreValue is a single tagged value out of a serialized
+// property storage section, keyed by its numeric property ID.
+type PropertyStoreValue struct {
+	ID    uint32      `json:"id"`
+	Type  uint16      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// PropertyStoreSection is one "1SPS" serialized property storage
+// blob, identified by its FMTID.
+type PropertyStoreSection struct {
+	FormatID string               `json:"format_id"`
+	Values   []PropertyStoreValue `json:"values"`
+}
+
+// PropertyStoreDataBlock holds the shell property bag attached to the
+// link target - e.g. the creator's SID or the original target path,
+// depending on which properties were serialized.
+type PropertyStoreDataBlock struct {
+	Sections []PropertyStoreSection `json:"sections"`
+}
+
+// EnvironmentVariableDataBlock carries an expandable (%…%-style) target
+// path, in both ANSI and Unicode form.
+type EnvironmentVariableDataBlock struct {
+	TargetAnsi    string `json:"target_ansi"`
+	TargetUnicode string `json:"target_unicode,omitempty"`
+}
+
+// IconEnvironmentDataBlock carries an expandable icon target path, in
+// both ANSI and Unicode form.
+type IconEnvironmentDataBlock struct {
+	TargetAnsi    string `json:"target_ansi"`
+	TargetUnicode string `json:"target_unicode,omitempty"`
+}
+
+// ConsoleDataBlock holds the font, screen buffer and window sizes, and
+// full-screen flag of a console-launching shortcut. The block's fill
+// attributes and color table are not kept.
+type ConsoleDataBlock struct {
+	FontFamily       uint32   `json:"font_family"`
+	FontSize         uint32   `json:"font_size"`
+	FontWeight       uint32   `json:"font_weight"`
+	FaceName         string   `json:"face_name"`
+	ScreenBufferSize [2]int16 `json:"screen_buffer_size"`
+	WindowSize       [2]int16 `json:"window_size"`
+	FullScreen       bool     `json:"full_screen"`
+}
+
+// KnownFolderDataBlock points at the target by KNOWNFOLDERID rather
+// than by path.
+type KnownFolderDataBlock struct {
+	KnownFolderID string `json:"known_folder_id"`
+	Offset        uint32 `json:"offset"`
+}
+
+// parseExtraData walks the ExtraData section, dispatching each block to
+// a parser keyed on its signature and falling through to a skip for any
+// signature this package doesn't recognize yet.
+func parseExtraData(r *binaryReader) (*ExtraData, error) {
+	out := &ExtraData{}
+
+	for {
+		var size uint32
+		if err := r.read(&size); err != nil {
+			return out, err
+		}
+		if size == 0 {
+			break
+		}
+		if size < 8 {
+			return out, errors.New("extra data block smaller than its own header")
+		}
+
+		var signature uint32
+		if err := r.read(&signature); err != nil {
+			return out, err
+		}
+
+		blockStart := r.pos - 8
+		blockEnd := blockStart + size
+		if blockEnd <= blockStart || blockEnd > uint32(len(r.data)) {
+			return out, errors.New("extra data block size out of range")
+		}
+
+		switch signature {
+		case sigTrackerDataBlock:
+			out.Tracker = parseTrackerDataBlock(r)
+		case sigPropertyStoreDataBlock:
+			out.PropertyStore = parsePropertyStoreDataBlock(r, blockEnd)
+		case sigEnvironmentVariableDataBlock:
+			out.EnvironmentVariable = parseEnvironmentVariableDataBlock(r)
+		case sigConsoleDataBlock:
+			out.Console = parseConsoleDataBlock(r)
+		case sigIconEnvironmentDataBlock:
+			out.IconEnvironment = parseIconEnvironmentDataBlock(r)
+		case sigKnownFolderDataBlock:
+			out.KnownFolder = parseKnownFolderDataBlock(r)
+		}
+
+		if err := r.seek(blockEnd); err != nil {
+			return out, err
+		}
+	}
+
+	return out, nil
+}
+
+func parseTrackerDataBlock(r *binaryReader) *TrackerDataBlock {
+	var length, version uint32
+	r.read(&length)
+	r.read(&version)
+
+	machineID, _ := r.readBytes(16)
+	droidVolume, _ := r.readBytes(16)
+	droidFile, _ := r.readBytes(16)
+	birthVolume, _ := r.readBytes(16)
+	birthFile, _ := r.readBytes(16)
+
+	tb := &TrackerDataBlock{
+		Version:    version,
+		MachineID:  cleanCString(machineID),
+		Droid:      [2]string{formatGUID(droidVolume), formatGUID(droidFile)},
+		DroidBirth: [2]string{formatGUID(birthVolume), formatGUID(birthFile)},
+	}
+
+	if len(birthVolume) == 16 {
+		mac := birthVolume[10:16]
+		tb.MacAddress = fmt.Sprintf("%02X:%02X:%02X:%02X:%02X:%02X", mac[0], mac[1], mac[2], mac[3], mac[4], mac[5])
+	}
+
+	return tb
+}
+
+func parsePropertyStoreDataBlock(r *binaryReader, blockEnd uint32) *PropertyStoreDataBlock {
+	ps := &PropertyStoreDataBlock{}
+
+	for r.pos+4 <= blockEnd {
+		var storageSize uint32
+		if err := r.read(&storageSize); err != nil {
+			break
+		}
+		if storageSize == 0 {
+			break
+		}
+
+		sectionStart := r.pos - 4
+		sectionEnd := sectionStart + storageSize
+		if sectionEnd <= sectionStart || sectionEnd > blockEnd {
+			break
+		}
+
+		var version uint32
+		r.read(&version)
+		formatID, _ := r.readBytes(16)
+
+		section := PropertyStoreSection{FormatID: formatGUID(formatID)}
+
+		for r.pos+4 <= sectionEnd {
+			var valueSize uint32
+			r.read(&valueSize)
+			if valueSize == 0 {
+				break
+			}
+			valueStart := r.pos - 4
+			valueEnd := valueStart + valueSize
+			if valueEnd <= valueStart || valueEnd > sectionEnd {
+				break
+			}
+
+			var id uint32
+			var reserved uint8
+			var typ uint16
+			r.read(&id)
+			r.read(&reserved)
+			r.read(&typ)
+			r.pos += 2 // padding before the value
+
+			section.Values = append(section.Values, PropertyStoreValue{
+				ID:    id,
+				Type:  typ,
+				Value: parsePropertyValue(r, typ, valueEnd),
+			})
+
+			r.seek(valueEnd)
+		}
+
+		ps.Sections = append(ps.Sections, section)
+		r.seek(sectionEnd)
+	}
+
+	return ps
+}
+
+func parsePropertyValue(r *binaryReader, typ uint16, valueEnd uint32) interface{} {
+	switch typ {
+	case 0x1F, 0x08: // VT_LPWSTR, VT_BSTR
+		var length uint32
+		if err := r.read(&length); err != nil {
+			return nil
+		}
+		b, err := r.readBytes(length * 2)
+		if err != nil {
+			return nil
+		}
+		return strings.TrimRight(decodeUTF16LE(b), "\x00")
+	case 0x13: // VT_UI4
+		var v uint32
+		r.read(&v)
+		return v
+	case 0x15: // VT_UI8
+		var v uint64
+		r.read(&v)
+		return v
+	case 0x0B: // VT_BOOL
+		var v uint16
+		r.read(&v)
+		return v != 0
+	case 0x48: // VT_CLSID
+		b, _ := r.readBytes(16)
+		return formatGUID(b)
+	default:
+		if r.pos >= valueEnd {
+			return nil
+		}
+		b, err := r.readBytes(valueEnd - r.pos)
+		if err != nil {
+			return nil
+		}
+		return base64.StdEncoding.EncodeToString(b)
+	}
+}
+
+func parseEnvironmentVariableDataBlock(r *binaryReader) *EnvironmentVariableDataBlock {
+	ansi, _ := r.readBytes(260)
+	unicode, _ := r.readBytes(520)
+	return &EnvironmentVariableDataBlock{
+		TargetAnsi:    cleanCString(ansi),
+		TargetUnicode: strings.TrimRight(decodeUTF16LE(unicode), "\x00"),
+	}
+}
+
+func parseIconEnvironmentDataBlock(r *binaryReader) *IconEnvironmentDataBlock {
+	ansi, _ := r.readBytes(260)
+	unicode, _ := r.readBytes(520)
+	return &IconEnvironmentDataBlock{
+		TargetAnsi:    cleanCString(ansi),
+		TargetUnicode: strings.TrimRight(decodeUTF16LE(unicode), "\x00"),
+	}
+}
+
+func parseConsoleDataBlock(r *binaryReader) *ConsoleDataBlock {
+	var fillAttr, popupFillAttr uint16
+	var bufX, bufY, winX, winY, originX, originY int16
+	r.read(&fillAttr)
+	r.read(&popupFillAttr)
+	r.read(&bufX)
+	r.read(&bufY)
+	r.read(&winX)
+	r.read(&winY)
+	r.read(&originX)
+	r.read(&originY)
+	r.pos += 8 // Unused1, Unused2
+
+	var fontSize, fontFamily, fontWeight uint32
+	r.read(&fontSize)
+	r.read(&fontFamily)
+	r.read(&fontWeight)
+
+	faceNameBytes, _ := r.readBytes(64)
+
+	r.pos += 4 // CursorSize
+	var fullScreen uint32
+	r.read(&fullScreen)
+	r.pos += 4 * 4 // QuickEdit, InsertMode, AutoPosition, HistoryBufferSize
+	r.pos += 4 * 2 // NumberOfHistoryBuffers, HistoryNoDup
+	r.pos += 64    // ColorTable
+
+	return &ConsoleDataBlock{
+		FontFamily:       fontFamily,
+		FontSize:         fontSize,
+		FontWeight:       fontWeight,
+		FaceName:         strings.TrimRight(decodeUTF16LE(faceNameBytes), "\x00"),
+		ScreenBufferSize: [2]int16{bufX, bufY},
+		WindowSize:       [2]int16{winX, winY},
+		FullScreen:       fullScreen != 0,
+	}
+}
+
+func parseKnownFolderDataBlock(r *binaryReader) *KnownFolderDataBlock {
+	guid, _ := r.readBytes(16)
+	var offset uint32
+	r.read(&offset)
+	return &KnownFolderDataBlock{
+		KnownFolderID: formatGUID(guid),
+		Offset:        offset,
+	}
+}
+
+// formatGUID renders 16 raw GUID bytes in the standard
+// XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX layout.
+func formatGUID(b []byte) string {
+	if len(b) != 16 {
+		return ""
+	}
+	return fmt.Sprintf("%08X-%04X-%04X-%04X-%012X",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		binary.BigEndian.Uint16(b[8:10]),
+		b[10:16])
+}
+
+// cleanCString trims a fixed-size, null-terminated ASCII byte buffer
+// down to the string it actually holds.
+func cleanCString(b []byte) string {
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// decodeUTF16LE decodes a raw UTF-16LE byte buffer (as used throughout
+// the .lnk format for Unicode strings).
+func decodeUTF16LE(b []byte) string {
+	if len(b)%2 != 0 {
+		b = b[:len(b)-1]
+	}
+	u16 := make([]uint16, len(b)/2)
+	for i := range u16 {
+		u16[i] = binary.LittleEndian.Uint16(b[i*2 : i*2+2])
+	}
+	return string(utf16.Decode(u16))
+}
@@ -0,0 +1,75 @@
+package lnk
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestParseExtraDataRejectsWraparoundSize(t *testing.T) {
+	// First block is well-formed and unrecognized (skipped), landing the
+	// reader at offset 16. The second block's declared size then wraps
+	// a uint32 so that blockStart(16) + size overflows back to 0, well
+	// behind the current read position. Before the fix, seek() happily
+	// jumped backward and the loop never terminated.
+	data := make([]byte, 24)
+	binary.LittleEndian.PutUint32(data[0:4], 16)         // block 1 size
+	binary.LittleEndian.PutUint32(data[4:8], 0xDEADBEEF) // unrecognized signature
+	binary.LittleEndian.PutUint32(data[16:20], 0xFFFFFFF0)
+	binary.LittleEndian.PutUint32(data[20:24], sigConsoleDataBlock)
+
+	r := &binaryReader{data: data}
+	done := make(chan error, 1)
+	go func() {
+		_, err := parseExtraData(r)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error for a wraparound block size, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("parseExtraData did not return: looks hung on a wraparound block size")
+	}
+}
+
+func TestParseExtraDataRejectsBlockPastEnd(t *testing.T) {
+	data := make([]byte, 16)
+	binary.LittleEndian.PutUint32(data[0:4], 0xFFFFFFFF) // size far larger than the buffer
+	binary.LittleEndian.PutUint32(data[4:8], sigConsoleDataBlock)
+
+	_, err := parseExtraData(&binaryReader{data: data})
+	if err == nil {
+		t.Fatal("expected an error for a block size past the end of the data, got nil")
+	}
+}
+
+func TestParseExtraDataTrackerBlock(t *testing.T) {
+	block := make([]byte, 0x60)
+	binary.LittleEndian.PutUint32(block[0:4], uint32(len(block)))
+	binary.LittleEndian.PutUint32(block[4:8], sigTrackerDataBlock)
+	binary.LittleEndian.PutUint32(block[8:12], uint32(len(block))) // length
+	binary.LittleEndian.PutUint32(block[12:16], 0)                 // version
+	copy(block[16:32], []byte("HOST"))                             // rest of the 16-byte field is already zero
+	birthVolume := block[64:80]
+	copy(birthVolume[10:16], []byte{0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF})
+
+	terminator := make([]byte, 4) // size == 0 marks the end of ExtraData
+	data := append(block, terminator...)
+
+	out, err := parseExtraData(&binaryReader{data: data})
+	if err != nil {
+		t.Fatalf("parseExtraData: %v", err)
+	}
+	if out.Tracker == nil {
+		t.Fatal("expected a parsed TrackerDataBlock")
+	}
+	if out.Tracker.MachineID != "HOST" {
+		t.Errorf("MachineID = %q, want %q", out.Tracker.MachineID, "HOST")
+	}
+	if out.Tracker.MacAddress != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("MacAddress = %q, want %q", out.Tracker.MacAddress, "AA:BB:CC:DD:EE:FF")
+	}
+}
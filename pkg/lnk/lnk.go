@@ -0,0 +1,281 @@
+// Package lnk parses Windows .lnk shortcut files (MS-SHLLINK).
+package lnk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+var lnkCLSID = []byte{
+	0x01, 0x14, 0x02, 0x00,
+	0x00, 0x00, 0x00, 0x00,
+	0xC0, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x46,
+}
+
+// Header is the fixed-size ShellLinkHeader structure.
+type Header struct {
+	CreationTime   *time.Time `json:"creation_time"`
+	AccessTime     *time.Time `json:"access_time"`
+	WriteTime      *time.Time `json:"write_time"`
+	FileSize       uint32     `json:"file_size"`
+	IconIndex      int32      `json:"icon_index"`
+	ShowCommand    uint32     `json:"show_command"`
+	HotKey         uint16     `json:"hot_key"`
+	FileAttributes uint32     `json:"file_attributes"`
+}
+
+// Target is the LinkInfo structure's path fields.
+type Target struct {
+	LocalBasePath    string `json:"local_base_path,omitempty"`
+	CommonPathSuffix string `json:"common_path_suffix,omitempty"`
+}
+
+// StringData is the set of optional StringData sections.
+type StringData struct {
+	Name             string `json:"name,omitempty"`
+	RelativePath     string `json:"relative_path,omitempty"`
+	WorkingDirectory string `json:"working_directory,omitempty"`
+	Arguments        string `json:"arguments,omitempty"`
+	IconLocation     string `json:"icon_location,omitempty"`
+}
+
+// Shortcut is a fully parsed .lnk file.
+type Shortcut struct {
+	Header    Header     `json:"header"`
+	Target    *Target    `json:"target,omitempty"`
+	Strings   StringData `json:"strings"`
+	ExtraData *ExtraData `json:"extra_data,omitempty"`
+}
+
+type binaryReader struct {
+	data []byte
+	pos  uint32
+}
+
+func (r *binaryReader) read(v any) error {
+	size := binary.Size(v)
+	if int(r.pos)+size > len(r.data) {
+		return errors.New("unexpected EOF")
+	}
+	buf := bytes.NewReader(r.data[r.pos : r.pos+uint32(size)])
+	err := binary.Read(buf, binary.LittleEndian, v)
+	r.pos += uint32(size)
+	return err
+}
+
+func (r *binaryReader) readBytes(n uint32) ([]byte, error) {
+	if int(r.pos+n) > len(r.data) {
+		return nil, errors.New("unexpected EOF")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *binaryReader) seek(pos uint32) error {
+	if int(pos) > len(r.data) {
+		return errors.New("seek beyond EOF")
+	}
+	r.pos = pos
+	return nil
+}
+
+func filetimeToTime(ft uint64) *time.Time {
+	if ft == 0 {
+		return nil
+	}
+	unix := int64((ft - 116444736000000000) / 10000000)
+	t := time.Unix(unix, 0).UTC()
+	return &t
+}
+
+func readCString(r *binaryReader) (string, error) {
+	var out []byte
+	for {
+		b, err := r.readBytes(1)
+		if err != nil {
+			return "", err
+		}
+		if b[0] == 0x00 {
+			break
+		}
+		out = append(out, b[0])
+	}
+	return string(out), nil
+}
+
+func readString(r *binaryReader, unicode bool) (string, error) {
+	var length uint16
+	if err := r.read(&length); err != nil {
+		return "", err
+	}
+
+	if unicode {
+		b, err := r.readBytes(uint32(length) * 2)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.TrimRight(b, "\x00")), nil
+	}
+
+	b, err := r.readBytes(uint32(length))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func parseHeader(r *binaryReader) (Header, uint32, error) {
+	var h Header
+
+	var headerSize uint32
+	if err := r.read(&headerSize); err != nil {
+		return h, 0, err
+	}
+
+	clsid, err := r.readBytes(16)
+	if err != nil {
+		return h, 0, err
+	}
+
+	if headerSize != 0x4C {
+		return h, 0, errors.New("invalid header size")
+	}
+	if !bytes.Equal(clsid, lnkCLSID) {
+		return h, 0, errors.New("invalid CLSID")
+	}
+
+	var linkFlags uint32
+	var fileAttr uint32
+	var ctime, atime, wtime uint64
+	var fileSize uint32
+	var iconIndex int32
+	var showCmd uint32
+	var hotKey uint16
+
+	r.read(&linkFlags)
+	r.read(&fileAttr)
+	r.read(&ctime)
+	r.read(&atime)
+	r.read(&wtime)
+	r.read(&fileSize)
+	r.read(&iconIndex)
+	r.read(&showCmd)
+	r.read(&hotKey)
+
+	r.pos += 10
+
+	h.CreationTime = filetimeToTime(ctime)
+	h.AccessTime = filetimeToTime(atime)
+	h.WriteTime = filetimeToTime(wtime)
+	h.FileSize = fileSize
+	h.IconIndex = iconIndex
+	h.ShowCommand = showCmd
+	h.HotKey = hotKey
+	h.FileAttributes = fileAttr
+
+	return h, linkFlags, nil
+}
+
+func parseLinkTargetIDList(r *binaryReader) error {
+	var size uint16
+	if err := r.read(&size); err != nil {
+		return err
+	}
+	data, err := r.readBytes(uint32(size))
+	if err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(data, []byte{0x00, 0x00}) {
+		return errors.New("invalid IDList terminator")
+	}
+	return nil
+}
+
+func parseLinkInfo(r *binaryReader) (*Target, error) {
+	start := r.pos
+
+	var size, headerSize, flags uint32
+	var volOff, localOff, netOff, commonOff uint32
+
+	r.read(&size)
+	r.read(&headerSize)
+	r.read(&flags)
+	r.read(&volOff)
+	r.read(&localOff)
+	r.read(&netOff)
+	r.read(&commonOff)
+
+	target := &Target{}
+
+	if localOff != 0 {
+		r.seek(start + localOff)
+		s, _ := readCString(r)
+		target.LocalBasePath = s
+	}
+
+	if commonOff != 0 {
+		r.seek(start + commonOff)
+		s, _ := readCString(r)
+		target.CommonPathSuffix = s
+	}
+
+	r.seek(start + size)
+	return target, nil
+}
+
+// ParseLNK parses a complete .lnk file read from r.
+func ParseLNK(r io.Reader) (*Shortcut, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	br := &binaryReader{data: data}
+
+	header, flags, err := parseHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &Shortcut{Header: header}
+
+	unicode := flags&0x80 != 0
+
+	if flags&0x01 != 0 {
+		if err := parseLinkTargetIDList(br); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags&0x02 != 0 {
+		sc.Target, _ = parseLinkInfo(br)
+	}
+
+	if flags&0x04 != 0 {
+		sc.Strings.Name, _ = readString(br, unicode)
+	}
+	if flags&0x08 != 0 {
+		sc.Strings.RelativePath, _ = readString(br, unicode)
+	}
+	if flags&0x10 != 0 {
+		sc.Strings.WorkingDirectory, _ = readString(br, unicode)
+	}
+	if flags&0x20 != 0 {
+		sc.Strings.Arguments, _ = readString(br, unicode)
+	}
+	if flags&0x40 != 0 {
+		sc.Strings.IconLocation, _ = readString(br, unicode)
+	}
+
+	sc.ExtraData, err = parseExtraData(br)
+	if err != nil {
+		return sc, err
+	}
+
+	return sc, nil
+}